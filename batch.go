@@ -0,0 +1,277 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/shurcooL/graphql/internal/jsonutil"
+)
+
+// BatchOptions configures the request coalescing enabled by WithBatching.
+type BatchOptions struct {
+	// MaxWait is how long to wait for more calls to arrive before flushing
+	// a batch that hasn't yet reached MaxSize.
+	MaxWait time.Duration
+	// MaxSize is the maximum number of operations sent in a single batch.
+	MaxSize int
+	// RequestTimeout bounds a flushed batch's HTTP round trip. A batch is
+	// sent from its own background goroutine, not any one caller's
+	// request, so none of the callers' contexts apply to it; zero means
+	// the request has no deadline of its own.
+	RequestTimeout time.Duration
+}
+
+// WithBatching merges Query and Mutate calls made within a small time window
+// into a single HTTP POST carrying a JSON array of operations (the de-facto
+// graphql-batch protocol), de-multiplexing the array response back to each
+// caller. Calls whose RequestOptions would produce different request
+// headers are never batched together.
+func WithBatching(opts BatchOptions) ClientOption {
+	return func(c *Client) { c.batcher = &batcher{c: c, opts: opts} }
+}
+
+// batchCall is a single Query or Mutate call awaiting a batch flush.
+type batchCall struct {
+	op        operationType
+	query     string
+	variables map[string]interface{}
+	v         interface{}
+	header    http.Header
+	done      chan error
+}
+
+// batcher accumulates batchCalls and flushes them as one HTTP request, via
+// a background timer or once BatchOptions.MaxSize is reached.
+type batcher struct {
+	c    *Client
+	opts BatchOptions
+
+	mu      sync.Mutex
+	pending []*batchCall
+	timer   *time.Timer
+}
+
+// do enqueues a single operation to be merged into the next batch and blocks
+// until its result is available.
+func (b *batcher) do(ctx context.Context, op operationType, query string, variables map[string]interface{}, v interface{}, opts []RequestOption) error {
+	header, err := b.c.headerFor(opts)
+	if err != nil {
+		return err
+	}
+	call := &batchCall{op: op, query: query, variables: variables, v: v, header: header, done: make(chan error, 1)}
+
+	b.mu.Lock()
+	b.pending = append(b.pending, call)
+	var batch []*batchCall
+	switch {
+	case len(b.pending) >= b.opts.MaxSize:
+		batch = b.pending
+		b.pending = nil
+		if b.timer != nil {
+			b.timer.Stop()
+		}
+	case len(b.pending) == 1:
+		b.timer = time.AfterFunc(b.opts.MaxWait, b.flush)
+	}
+	b.mu.Unlock()
+
+	if batch != nil {
+		go b.send(batch)
+	}
+
+	select {
+	case err := <-call.done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// flush sends whatever has accumulated since the last flush. It runs from
+// the MaxWait timer, independent of any one caller's context.
+func (b *batcher) flush() {
+	b.mu.Lock()
+	batch := b.pending
+	b.pending = nil
+	b.mu.Unlock()
+	if len(batch) > 0 {
+		b.send(batch)
+	}
+}
+
+// send groups batch by identical request headers and issues one HTTP
+// request per group, falling back to sending each call in the group
+// individually if the server rejects a batched array request.
+func (b *batcher) send(batch []*batchCall) {
+	ctx, cancel := b.requestContext()
+	defer cancel()
+
+	for _, group := range groupByHeader(batch) {
+		if len(group) == 1 {
+			b.sendIndividually(ctx, group)
+			continue
+		}
+		if err := b.sendBatch(ctx, group); err != nil {
+			b.sendIndividually(ctx, group)
+		}
+	}
+}
+
+// requestContext returns the context a flushed batch's HTTP request(s)
+// should run under. A batch is sent from its own background goroutine, not
+// any one caller's request, so it gets its own bound instead of inheriting
+// (or ignoring) the callers' contexts.
+func (b *batcher) requestContext() (context.Context, context.CancelFunc) {
+	if b.opts.RequestTimeout <= 0 {
+		return context.Background(), func() {}
+	}
+	return context.WithTimeout(context.Background(), b.opts.RequestTimeout)
+}
+
+// groupByHeader partitions batch into groups of calls with identical
+// headers, whether or not those calls are adjacent, preserving relative
+// order within each group.
+func groupByHeader(batch []*batchCall) [][]*batchCall {
+	var groups [][]*batchCall
+	for _, call := range batch {
+		placed := false
+		for i, g := range groups {
+			if headersEqual(g[0].header, call.header) {
+				groups[i] = append(g, call)
+				placed = true
+				break
+			}
+		}
+		if !placed {
+			groups = append(groups, []*batchCall{call})
+		}
+	}
+	return groups
+}
+
+func headersEqual(a, b http.Header) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if !reflect.DeepEqual(v, b[k]) {
+			return false
+		}
+	}
+	return true
+}
+
+// sendBatch sends group as a single `[{query,variables},...]` POST request.
+// A non-nil error means the server didn't accept the batched form at all,
+// and none of group's calls have been resolved yet.
+func (b *batcher) sendBatch(ctx context.Context, group []*batchCall) error {
+	type op struct {
+		Query     string                 `json:"query"`
+		Variables map[string]interface{} `json:"variables,omitempty"`
+	}
+	ops := make([]op, len(group))
+	for i, call := range group {
+		ops[i] = op{Query: call.query, Variables: call.variables}
+	}
+
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(ops); err != nil {
+		failAll(group, err)
+		return nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, b.c.url, &buf)
+	if err != nil {
+		failAll(group, err)
+		return nil
+	}
+	req.Header = group[0].header.Clone()
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := b.c.httpClient.Do(req)
+	if err != nil {
+		failAll(group, err)
+		return nil
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("batch request rejected: non-200 OK status code: %v", resp.Status)
+	}
+
+	var out []struct {
+		Data   *json.RawMessage
+		Errors GraphQLErrors
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return err
+	}
+	if len(out) != len(group) {
+		return fmt.Errorf("batch response had %d entries, want %d", len(out), len(group))
+	}
+
+	for i, call := range group {
+		call.done <- decodeResult(out[i].Data, out[i].Errors, call.v)
+	}
+	return nil
+}
+
+// sendIndividually sends each call in group as its own HTTP request. Used as
+// the fallback when a server doesn't support the graphql-batch protocol.
+func (b *batcher) sendIndividually(ctx context.Context, group []*batchCall) {
+	for _, call := range group {
+		req, err := b.c.newRequest(ctx, call.op, call.query, nil, call.variables)
+		if err != nil {
+			call.done <- err
+			continue
+		}
+		req.Header = call.header.Clone()
+
+		resp, err := b.c.httpClient.Do(req)
+		if err != nil {
+			call.done <- err
+			continue
+		}
+		var out struct {
+			Data   *json.RawMessage
+			Errors GraphQLErrors
+		}
+		err = json.NewDecoder(resp.Body).Decode(&out)
+		resp.Body.Close()
+		if err != nil {
+			call.done <- err
+			continue
+		}
+		call.done <- decodeResult(out.Data, out.Errors, call.v)
+	}
+}
+
+func failAll(group []*batchCall, err error) {
+	for _, call := range group {
+		call.done <- err
+	}
+}
+
+// decodeResult unmarshals a single operation's data/errors pair into v,
+// mirroring Client.roundTrip's handling of a non-batched response.
+func decodeResult(data *json.RawMessage, errs GraphQLErrors, v interface{}) error {
+	if data != nil {
+		if err := jsonutil.UnmarshalGraphQL(*data, v); err != nil {
+			return err
+		}
+		if len(errs) > 0 {
+			return &PartialDataError{Errors: errs}
+		}
+		return nil
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
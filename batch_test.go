@@ -0,0 +1,62 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWithBatchingCoalescesConcurrentCalls(t *testing.T) {
+	var mu sync.Mutex
+	var requestSizes []int
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var ops []json.RawMessage
+		_ = json.NewDecoder(r.Body).Decode(&ops)
+
+		mu.Lock()
+		requestSizes = append(requestSizes, len(ops))
+		mu.Unlock()
+
+		resp := make([]map[string]interface{}, len(ops))
+		for i := range ops {
+			resp[i] = map[string]interface{}{"data": map[string]interface{}{"value": i}}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer srv.Close()
+
+	c := NewClient(srv.URL, nil, WithBatching(BatchOptions{MaxWait: 20 * time.Millisecond, MaxSize: 10}))
+
+	const n = 5
+	var wg sync.WaitGroup
+	errs := make([]error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			var q struct {
+				Value int `graphql:"value"`
+			}
+			errs[i] = c.Query(context.Background(), &q, nil)
+		}(i)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			t.Errorf("call %d: %v", i, err)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(requestSizes) != 1 || requestSizes[0] != n {
+		t.Errorf("requestSizes = %v, want a single batch of %d", requestSizes, n)
+	}
+}
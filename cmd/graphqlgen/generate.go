@@ -0,0 +1,342 @@
+package main
+
+import (
+	"fmt"
+	"go/format"
+	"sort"
+	"strings"
+
+	"github.com/shurcooL/graphql/schema"
+)
+
+// builtinScalars maps the built-in GraphQL scalars to their Go equivalents.
+var builtinScalars = map[string]string{
+	"ID":      "string",
+	"String":  "string",
+	"Int":     "int",
+	"Float":   "float64",
+	"Boolean": "bool",
+}
+
+// generator turns a parsed Schema and Document into Go source implementing
+// one typed wrapper function per operation.
+type generator struct {
+	schema *schema.Schema
+	doc    *schema.Document
+	cfg    *schema.Config
+
+	buf     strings.Builder
+	structs strings.Builder
+	enums   map[string]bool // Emitted enum type names, to avoid duplicates.
+	imports map[string]bool
+}
+
+// Generate renders doc (parsed against sch, using cfg for package name and
+// custom scalar mappings) into a single formatted Go source file.
+func Generate(sch *schema.Schema, doc *schema.Document, cfg *schema.Config) ([]byte, error) {
+	g := &generator{
+		schema:  sch,
+		doc:     doc,
+		cfg:     cfg,
+		enums:   map[string]bool{},
+		imports: map[string]bool{"context": true, "github.com/shurcooL/graphql": true},
+	}
+
+	for _, op := range doc.Operations {
+		if err := g.genOperation(op); err != nil {
+			return nil, err
+		}
+	}
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "// Code generated by graphqlgen. DO NOT EDIT.\n\npackage %s\n\nimport (\n", g.cfg.Package)
+	paths := make([]string, 0, len(g.imports))
+	for p := range g.imports {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	for _, p := range paths {
+		fmt.Fprintf(&out, "\t%q\n", p)
+	}
+	out.WriteString(")\n\n")
+	out.WriteString(g.structs.String())
+	out.WriteString(g.buf.String())
+
+	return format.Source([]byte(out.String()))
+}
+
+// genOperation emits the response struct hierarchy and typed wrapper
+// function for a single operation.
+func (g *generator) genOperation(op *schema.Operation) error {
+	if op.Name == "" {
+		return fmt.Errorf("graphqlgen: anonymous operations are not supported, give this operation a name")
+	}
+
+	rootType := g.schema.Query
+	switch op.Kind {
+	case schema.OpMutation:
+		rootType = g.schema.Mutation
+	case schema.OpSubscription:
+		rootType = g.schema.Subscription
+	}
+
+	respName := op.Name + "Response"
+	if err := g.genStruct(respName, rootType, op.Selection); err != nil {
+		return err
+	}
+
+	fnName := strings.ToUpper(op.Name[:1]) + op.Name[1:]
+	var params, varsBody strings.Builder
+	params.WriteString("ctx context.Context, c *graphql.Client")
+	for _, v := range op.Variables {
+		goType, err := g.goTypeOf(v.Type, "")
+		if err != nil {
+			return err
+		}
+		paramName := lowerFirst(v.Name)
+		fmt.Fprintf(&params, ", %s %s", paramName, goType)
+		fmt.Fprintf(&varsBody, "\t\t%q: %s,\n", v.Name, paramName)
+	}
+
+	call := "c.Query"
+	if op.Kind == schema.OpMutation {
+		call = "c.Mutate"
+	}
+
+	fmt.Fprintf(&g.buf, `
+// %s executes the %q %s.
+func %s(%s) (*%s, error) {
+	var q %s
+	variables := map[string]interface{}{
+%s	}
+	if err := %s(ctx, &q, variables); err != nil {
+		return nil, err
+	}
+	return &q, nil
+}
+`, fnName, op.Name, operationKindName(op.Kind), fnName, params.String(), respName, respName, varsBody.String(), call)
+
+	return nil
+}
+
+func operationKindName(k schema.OperationKind) string {
+	switch k {
+	case schema.OpMutation:
+		return "mutation"
+	case schema.OpSubscription:
+		return "subscription"
+	default:
+		return "query"
+	}
+}
+
+// genStruct emits a Go struct named name for the GraphQL type typeName,
+// restricted to the fields in sels, plus any nested structs it needs.
+func (g *generator) genStruct(name, typeName string, sels []*schema.Selection) error {
+	def := g.schema.Types[typeName]
+	if def == nil {
+		return fmt.Errorf("graphqlgen: unknown type %q", typeName)
+	}
+
+	var fields strings.Builder
+	for _, sel := range sels {
+		if sel.FragmentSpread {
+			frag, ok := g.doc.Fragments[sel.Name]
+			if !ok {
+				return fmt.Errorf("graphqlgen: unknown fragment %q", sel.Name)
+			}
+			if err := g.genFields(&fields, name, def, frag.Selection); err != nil {
+				return err
+			}
+			continue
+		}
+		if sel.Name == "" {
+			// Inline fragment ("... on Type { ... }"): flatten its fields into
+			// the parent struct. Narrowing to the fragment's own type isn't
+			// supported yet, so its fields are resolved against def.
+			if err := g.genFields(&fields, name, def, sel.Selection); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := g.genField(&fields, name, def, sel); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintf(&g.structs, "type %s struct {\n%s}\n\n", name, fields.String())
+	return nil
+}
+
+func (g *generator) genFields(out *strings.Builder, parentName string, def *schema.TypeDef, sels []*schema.Selection) error {
+	for _, sel := range sels {
+		if sel.FragmentSpread {
+			frag, ok := g.doc.Fragments[sel.Name]
+			if !ok {
+				return fmt.Errorf("graphqlgen: unknown fragment %q", sel.Name)
+			}
+			if err := g.genFields(out, parentName, def, frag.Selection); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := g.genField(out, parentName, def, sel); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// genField emits a single struct field line for sel, recursing into
+// genStruct first if sel has a nested selection set.
+func (g *generator) genField(out *strings.Builder, parentName string, def *schema.TypeDef, sel *schema.Selection) error {
+	fieldDef := fieldByName(def, sel.Name)
+	if fieldDef == nil {
+		return fmt.Errorf("graphqlgen: type %q has no field %q", def.Name, sel.Name)
+	}
+
+	goName := strings.ToUpper(sel.Name[:1]) + sel.Name[1:]
+	if sel.Alias != "" {
+		goName = strings.ToUpper(sel.Alias[:1]) + sel.Alias[1:]
+	}
+
+	leafType := baseTypeName(fieldDef.Type)
+	var goLeaf string
+	if sel.Selection != nil {
+		nestedName := parentName + goName
+		if err := g.genStruct(nestedName, leafType, sel.Selection); err != nil {
+			return err
+		}
+		goLeaf = nestedName
+	} else {
+		t, err := g.leafGoType(leafType)
+		if err != nil {
+			return err
+		}
+		goLeaf = t
+	}
+
+	goType, err := g.goTypeOf(fieldDef.Type, goLeaf)
+	if err != nil {
+		return err
+	}
+
+	tag := sel.Name
+	if sel.Alias != "" {
+		tag = sel.Alias + ":" + sel.Name
+	}
+	if len(sel.Args) > 0 {
+		tag += "(" + g.renderArgs(sel.Args) + ")"
+	}
+	fmt.Fprintf(out, "\t%s %s `graphql:%q`\n", goName, goType, tag)
+	return nil
+}
+
+func (g *generator) renderArgs(args map[string]schema.ArgValue) string {
+	names := make([]string, 0, len(args))
+	for n := range args {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	parts := make([]string, len(names))
+	for i, n := range names {
+		v := args[n]
+		if v.Variable != "" {
+			parts[i] = fmt.Sprintf("%s: $%s", n, v.Variable)
+		} else {
+			parts[i] = fmt.Sprintf("%s: %s", n, v.Raw)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// goTypeOf wraps leaf (already resolved) with the []/* nesting implied by
+// ref's list/non-null structure. An empty leaf resolves it directly.
+func (g *generator) goTypeOf(ref *schema.TypeRef, leaf string) (string, error) {
+	if leaf == "" {
+		var err error
+		leaf, err = g.leafGoType(baseTypeName(ref))
+		if err != nil {
+			return "", err
+		}
+	}
+	return wrapType(ref, leaf), nil
+}
+
+func wrapType(ref *schema.TypeRef, leaf string) string {
+	if ref.List != nil {
+		return "[]" + wrapType(ref.List, leaf)
+	}
+	if ref.NonNull {
+		return leaf
+	}
+	return "*" + leaf
+}
+
+// leafGoType resolves name (a scalar, enum or object type name) to the Go
+// type used for it, emitting an enum type definition the first time an enum
+// is encountered.
+func (g *generator) leafGoType(name string) (string, error) {
+	if t, ok := builtinScalars[name]; ok {
+		return t, nil
+	}
+	if sc, ok := g.cfg.Scalars[name]; ok {
+		if sc.Import != "" {
+			g.imports[sc.Import] = true
+		}
+		return sc.Type, nil
+	}
+
+	def := g.schema.Types[name]
+	if def == nil {
+		return "", fmt.Errorf("graphqlgen: unknown type %q (add it to the schema or config scalars)", name)
+	}
+	switch def.Kind {
+	case schema.KindScalar:
+		return "string", nil // Unmapped custom scalar: fall back to its wire representation.
+	case schema.KindEnum:
+		g.genEnum(def)
+		return name, nil
+	default:
+		return name, nil // Referenced directly (e.g. without a selection set is invalid, but be permissive).
+	}
+}
+
+func (g *generator) genEnum(def *schema.TypeDef) {
+	if g.enums[def.Name] {
+		return
+	}
+	g.enums[def.Name] = true
+
+	fmt.Fprintf(&g.structs, "type %s string\n\nconst (\n", def.Name)
+	for _, v := range def.EnumValues {
+		fmt.Fprintf(&g.structs, "\t%s%s %s = %q\n", def.Name, v, def.Name, v)
+	}
+	g.structs.WriteString(")\n\n")
+}
+
+func fieldByName(def *schema.TypeDef, name string) *schema.FieldDef {
+	if name == "__typename" {
+		return &schema.FieldDef{Name: name, Type: &schema.TypeRef{Name: "String", NonNull: true}}
+	}
+	for _, f := range def.Fields {
+		if f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+func baseTypeName(ref *schema.TypeRef) string {
+	if ref.List != nil {
+		return baseTypeName(ref.List)
+	}
+	return ref.Name
+}
+
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToLower(s[:1]) + s[1:]
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/shurcooL/graphql/schema"
+)
+
+func TestGenOperationRejectsAnonymousOperations(t *testing.T) {
+	sch, err := schema.Parse(`type Query { hello: String }`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err := schema.ParseDocument(`query { hello }`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := &schema.Config{Package: "main", Scalars: map[string]schema.ScalarConfig{}}
+
+	if _, err := Generate(sch, doc, cfg); err == nil {
+		t.Fatal("Generate succeeded on an anonymous operation, want an error")
+	}
+}
+
+func TestGenerateOnlyImportsScalarsActuallyUsed(t *testing.T) {
+	sch, err := schema.Parse(`
+		scalar DateTime
+		type Query { hello: String, createdAt: DateTime }
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err := schema.ParseDocument(`query Foo { hello }`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := &schema.Config{
+		Package: "main",
+		Scalars: map[string]schema.ScalarConfig{
+			"DateTime": {Type: "time.Time", Import: "time"},
+		},
+	}
+
+	src, err := Generate(sch, doc, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(src), `"time"`) {
+		t.Errorf("generated source imports %q despite DateTime never being selected:\n%s", "time", src)
+	}
+}
@@ -0,0 +1,85 @@
+// Command graphqlgen generates typed Go query functions from a GraphQL SDL
+// schema and a set of .graphql operation documents, so that callers don't
+// have to hand-write response struct hierarchies for large schemas.
+//
+// Usage:
+//
+//	graphqlgen -schema schema.graphql -config graphqlgen.json -out gen.go query1.graphql query2.graphql
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/shurcooL/graphql/schema"
+)
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintln(os.Stderr, "graphqlgen:", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	schemaPath := flag.String("schema", "", "path to the GraphQL SDL schema file")
+	configPath := flag.String("config", "", "path to a JSON config file (package name, custom scalar mappings)")
+	outPath := flag.String("out", "", "path to write the generated Go source to (default: stdout)")
+	flag.Parse()
+
+	if *schemaPath == "" || flag.NArg() == 0 {
+		flag.Usage()
+		return fmt.Errorf("usage: graphqlgen -schema schema.graphql [-config graphqlgen.json] [-out gen.go] operation.graphql ...")
+	}
+
+	sdl, err := os.ReadFile(*schemaPath)
+	if err != nil {
+		return err
+	}
+	sch, err := schema.Parse(string(sdl))
+	if err != nil {
+		return fmt.Errorf("parsing %s: %w", *schemaPath, err)
+	}
+
+	var cfg *schema.Config
+	if *configPath != "" {
+		cfg, err = schema.LoadConfig(*configPath)
+		if err != nil {
+			return err
+		}
+	} else {
+		cfg = &schema.Config{Package: "main", Scalars: map[string]schema.ScalarConfig{}}
+	}
+
+	doc := &schema.Document{Fragments: map[string]*schema.Fragment{}}
+	for _, path := range flag.Args() {
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		d, err := schema.ParseDocument(string(src))
+		if err != nil {
+			return fmt.Errorf("parsing %s: %w", path, err)
+		}
+		doc.Operations = append(doc.Operations, d.Operations...)
+		for name, f := range d.Fragments {
+			doc.Fragments[name] = f
+		}
+	}
+
+	src, err := Generate(sch, doc, cfg)
+	if err != nil {
+		return err
+	}
+
+	if *outPath == "" {
+		_, err := os.Stdout.Write(src)
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(*outPath), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(*outPath, src, 0o644)
+}
@@ -1,9 +1,106 @@
 package graphql
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 // OptionError represents an error modifiying a request.
 type OptionError struct{ Err error }
 
 func (e *OptionError) Error() string { return fmt.Sprintf("request option error: %v", e.Err) }
 func (e *OptionError) Unwrap() error { return e.Err }
+
+// GraphQLError represents a single entry of the "errors" array in a response
+// from a GraphQL server.
+//
+// Specification: https://facebook.github.io/graphql/#sec-Errors.
+type GraphQLError struct {
+	Message   string `json:"message"`
+	Locations []struct {
+		Line   int `json:"line"`
+		Column int `json:"column"`
+	} `json:"locations"`
+	Path       []interface{}          `json:"path"`
+	Extensions map[string]interface{} `json:"extensions"`
+}
+
+// Error implements the error interface.
+func (e GraphQLError) Error() string { return e.Message }
+
+// Code returns the value of the conventional "code" extension, or the empty
+// string if it isn't set.
+func (e GraphQLError) Code() string {
+	code, _ := e.Extensions["code"].(string)
+	return code
+}
+
+// Is reports whether target is a GraphQLError (or *GraphQLError) with the
+// same Code, so that callers can use errors.Is(err, &graphql.GraphQLError{Extensions: ...}).
+func (e GraphQLError) Is(target error) bool {
+	var other GraphQLError
+	switch t := target.(type) {
+	case GraphQLError:
+		other = t
+	case *GraphQLError:
+		other = *t
+	default:
+		return false
+	}
+	code := e.Code()
+	return code != "" && code == other.Code()
+}
+
+// PathString renders Path as a dotted string, e.g. "createUser.profile.bio".
+func (e GraphQLError) PathString() string {
+	parts := make([]string, len(e.Path))
+	for i, p := range e.Path {
+		parts[i] = fmt.Sprint(p)
+	}
+	return strings.Join(parts, ".")
+}
+
+// GraphQLErrors represents the "errors" array in a response from a GraphQL
+// server. If returned via the error interface, the slice is expected to
+// contain at least 1 element.
+//
+// Specification: https://facebook.github.io/graphql/#sec-Errors.
+// Actual implementation:
+// https://github.com/spacelift-io/graphql-go/blob/4c5b960673418ee4577498869c8dfa2c66628458/GraphQLErrors/GraphQLErrors.go#L7
+type GraphQLErrors []GraphQLError
+
+// Error implements the error interface.
+func (e GraphQLErrors) Error() string { return e[0].Message }
+
+// Is reports whether any error in e matches target, so that callers can use
+// errors.Is(err, &graphql.GraphQLError{Extensions: ...}) against a
+// GraphQLErrors value.
+func (e GraphQLErrors) Is(target error) bool {
+	for _, ge := range e {
+		if ge.Is(target) {
+			return true
+		}
+	}
+	return false
+}
+
+// PartialDataError is returned by Client.Query and Client.Mutate when the
+// server's response contained both data and errors. Data has already been
+// unmarshaled into the struct passed by the caller; Errors holds the
+// GraphQL-level errors that accompanied it.
+//
+// Callers can use errors.As to distinguish a partial success from a
+// transport-level failure.
+type PartialDataError struct {
+	Errors GraphQLErrors
+}
+
+// Error implements the error interface.
+func (e *PartialDataError) Error() string {
+	return fmt.Sprintf("partial data: %v", e.Errors.Error())
+}
+
+// Unwrap returns the underlying GraphQLErrors, so that errors.As(err, &graphql.GraphQLErrors{}) also works.
+func (e *PartialDataError) Unwrap() error { return e.Errors }
+
+var _ error = (*PartialDataError)(nil)
@@ -0,0 +1,89 @@
+package graphql
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestGraphQLErrorCode(t *testing.T) {
+	e := GraphQLError{Extensions: map[string]interface{}{"code": "NOT_FOUND"}}
+	if got := e.Code(); got != "NOT_FOUND" {
+		t.Errorf("Code() = %q, want %q", got, "NOT_FOUND")
+	}
+	if got := (GraphQLError{}).Code(); got != "" {
+		t.Errorf("Code() with no extensions = %q, want empty string", got)
+	}
+}
+
+func TestGraphQLErrorIs(t *testing.T) {
+	e := GraphQLError{Message: "boom", Extensions: map[string]interface{}{"code": "NOT_FOUND"}}
+
+	if !errors.Is(e, &GraphQLError{Extensions: map[string]interface{}{"code": "NOT_FOUND"}}) {
+		t.Error("errors.Is with matching code = false, want true")
+	}
+	if errors.Is(e, &GraphQLError{Extensions: map[string]interface{}{"code": "OTHER"}}) {
+		t.Error("errors.Is with mismatched code = true, want false")
+	}
+	if errors.Is(e, &GraphQLError{}) {
+		t.Error("errors.Is against an error with no code = true, want false")
+	}
+}
+
+func TestGraphQLErrorPathString(t *testing.T) {
+	e := GraphQLError{Path: []interface{}{"createUser", "profile", "bio"}}
+	if got := e.PathString(); got != "createUser.profile.bio" {
+		t.Errorf("PathString() = %q, want %q", got, "createUser.profile.bio")
+	}
+}
+
+func TestGraphQLErrorsIs(t *testing.T) {
+	errs := GraphQLErrors{
+		{Message: "first", Extensions: map[string]interface{}{"code": "A"}},
+		{Message: "second", Extensions: map[string]interface{}{"code": "B"}},
+	}
+	if !errs.Is(&GraphQLError{Extensions: map[string]interface{}{"code": "B"}}) {
+		t.Error("GraphQLErrors.Is did not find a matching element")
+	}
+	if errs.Is(&GraphQLError{Extensions: map[string]interface{}{"code": "C"}}) {
+		t.Error("GraphQLErrors.Is matched a code that isn't present")
+	}
+}
+
+func TestDecodeResultPartialSuccess(t *testing.T) {
+	data := json.RawMessage(`{"value":1}`)
+	errs := GraphQLErrors{{Message: "degraded"}}
+
+	var v struct {
+		Value int `graphql:"value"`
+	}
+	err := decodeResult(&data, errs, &v)
+
+	var partial *PartialDataError
+	if !errors.As(err, &partial) {
+		t.Fatalf("decodeResult error = %v, want *PartialDataError", err)
+	}
+	if len(partial.Errors) != 1 || partial.Errors[0].Message != "degraded" {
+		t.Errorf("partial.Errors = %+v, want [{Message: degraded}]", partial.Errors)
+	}
+	if v.Value != 1 {
+		t.Errorf("v.Value = %d, want 1 (data should still be decoded alongside the errors)", v.Value)
+	}
+}
+
+func TestDecodeResultErrorsWithoutData(t *testing.T) {
+	errs := GraphQLErrors{{Message: "not found"}}
+
+	var v struct {
+		Value int `graphql:"value"`
+	}
+	err := decodeResult(nil, errs, &v)
+
+	var got GraphQLErrors
+	if !errors.As(err, &got) {
+		t.Fatalf("decodeResult error = %v, want GraphQLErrors", err)
+	}
+	if v.Value != 0 {
+		t.Errorf("v.Value = %d, want 0 (no data should have been decoded)", v.Value)
+	}
+}
@@ -7,8 +7,6 @@ import (
 	"fmt"
 	"io"
 	"net/http"
-
-	"github.com/shurcooL/graphql/internal/jsonutil"
 )
 
 // Client is a GraphQL client.
@@ -16,19 +14,29 @@ type Client struct {
 	url            string // GraphQL server URL.
 	httpClient     *http.Client
 	requestOptions []RequestOption
+
+	persistedQueries PersistedQueryCache
+	getForQueries    bool
+
+	batcher *batcher
+
+	maxUploadSize int64
 }
 
 // NewClient creates a GraphQL client targeting the specified GraphQL server URL.
 // If httpClient is nil, then http.DefaultClient is used.
-func NewClient(url string, httpClient *http.Client, opts ...RequestOption) *Client {
+func NewClient(url string, httpClient *http.Client, opts ...ClientOption) *Client {
 	if httpClient == nil {
 		httpClient = http.DefaultClient
 	}
-	return &Client{
-		url:            url,
-		httpClient:     httpClient,
-		requestOptions: opts,
+	c := &Client{
+		url:        url,
+		httpClient: httpClient,
+	}
+	for _, opt := range opts {
+		opt(c)
 	}
+	return c
 }
 
 // Query executes a single GraphQL query request,
@@ -54,24 +62,52 @@ func (c *Client) do(ctx context.Context, op operationType, v interface{}, variab
 	case mutationOperation:
 		query = constructMutation(v, variables)
 	}
-	in := struct {
-		Query     string         `json:"query"`
-		Variables map[string]any `json:"variables,omitempty"`
-	}{
-		Query:     query,
-		Variables: variables,
+
+	if uploads := findUploads("variables", variables); len(uploads) > 0 {
+		return c.doUpload(ctx, query, variables, v, uploads, opts)
 	}
-	var buf bytes.Buffer
-	err := json.NewEncoder(&buf).Encode(in)
+	if c.batcher != nil {
+		return c.batcher.do(ctx, op, query, variables, v, opts)
+	}
+	if c.persistedQueries != nil {
+		return c.doPersisted(ctx, op, query, variables, v, opts)
+	}
+	return c.roundTrip(ctx, op, query, nil, variables, v, opts)
+}
+
+// headerFor resolves the http.Header that opts (together with the client's
+// default RequestOptions) would produce against a throwaway request, without
+// sending anything. It's used to decide whether two operations can share a
+// single batched HTTP request.
+func (c *Client) headerFor(opts []RequestOption) (http.Header, error) {
+	req, err := http.NewRequest(http.MethodPost, c.url, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, &buf)
+
+	var allOpts []RequestOption
+	allOpts = append(allOpts, c.requestOptions...)
+	allOpts = append(allOpts, opts...)
+	for _, opt := range allOpts {
+		if err := opt(req); err != nil {
+			return nil, &OptionError{Err: err}
+		}
+	}
+	return req.Header, nil
+}
+
+// roundTrip sends a single GraphQL request built from query, ext and
+// variables, and decodes the response into v.
+func (c *Client) roundTrip(ctx context.Context, op operationType, query string, ext map[string]interface{}, variables map[string]interface{}, v interface{}, opts []RequestOption) error {
+	req, err := c.newRequest(ctx, op, query, ext, variables)
 	if err != nil {
 		return err
 	}
-	req.Header.Set("Content-Type", "application/json")
+	return c.execute(req, opts, v)
+}
 
+// execute applies opts to req, sends it, and decodes the response into v.
+func (c *Client) execute(req *http.Request, opts []RequestOption, v interface{}) error {
 	var allOpts []RequestOption
 	allOpts = append(allOpts, c.requestOptions...)
 	allOpts = append(allOpts, opts...)
@@ -95,43 +131,40 @@ func (c *Client) do(ctx context.Context, op operationType, v interface{}, variab
 		Data   *json.RawMessage
 		Errors GraphQLErrors
 	}
-	err = json.NewDecoder(resp.Body).Decode(&out)
-	if err != nil {
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
 		// TODO: Consider including response body in returned error, if deemed helpful.
 		return err
 	}
-	if out.Data != nil {
-		err := jsonutil.UnmarshalGraphQL(*out.Data, v)
-		if err != nil {
-			// TODO: Consider including response body in returned error, if deemed helpful.
-			return err
-		}
-	}
-	if len(out.Errors) > 0 {
-		return out.Errors
-	}
-	return nil
+	return decodeResult(out.Data, out.Errors, v)
 }
 
-// GraphQLErrors represents the "GraphQLErrors" array in a response from a GraphQL server.
-// If returned via error interface, the slice is expected to contain at least 1 element.
-//
-// Specification: https://facebook.github.io/graphql/#sec-Errors.
-// Actual implementation:
-// https://github.com/spacelift-io/graphql-go/blob/4c5b960673418ee4577498869c8dfa2c66628458/GraphQLErrors/GraphQLErrors.go#L7
-type GraphQLErrors []struct {
-	Message   string
-	Locations []struct {
-		Line   int
-		Column int
-	}
-	Path       []interface{}
-	Extensions map[string]interface{}
-}
+// newRequest builds the *http.Request for a single GraphQL operation. Queries
+// are sent as a cacheable GET request when WithGETForQueries is enabled;
+// everything else is a JSON POST.
+func (c *Client) newRequest(ctx context.Context, op operationType, query string, ext map[string]interface{}, variables map[string]interface{}) (*http.Request, error) {
+	if c.getForQueries && op == queryOperation {
+		return c.newGetRequest(ctx, query, ext, variables)
+	}
 
-// Error implements error interface.
-func (e GraphQLErrors) Error() string {
-	return e[0].Message
+	in := struct {
+		Query      string                 `json:"query"`
+		Variables  map[string]any         `json:"variables,omitempty"`
+		Extensions map[string]interface{} `json:"extensions,omitempty"`
+	}{
+		Query:      query,
+		Variables:  variables,
+		Extensions: ext,
+	}
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(in); err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, &buf)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return req, nil
 }
 
 type operationType uint8
@@ -139,5 +172,5 @@ type operationType uint8
 const (
 	queryOperation operationType = iota
 	mutationOperation
-	//subscriptionOperation // Unused.
+	subscriptionOperation
 )
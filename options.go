@@ -0,0 +1,13 @@
+package graphql
+
+// ClientOption configures a Client at construction time, via NewClient.
+type ClientOption func(*Client)
+
+// WithRequestOptions sets RequestOptions that are applied to every request
+// issued by the client, in addition to any passed directly to Query, Mutate
+// or Subscribe.
+func WithRequestOptions(opts ...RequestOption) ClientOption {
+	return func(c *Client) {
+		c.requestOptions = append(c.requestOptions, opts...)
+	}
+}
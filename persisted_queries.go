@@ -0,0 +1,121 @@
+package graphql
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+)
+
+// PersistedQueryCache stores the mapping between a query's SHA-256 hash and
+// its full text, so that Client can avoid resending the text once the
+// server has confirmed it knows the query. Implementations must be safe for
+// concurrent use; an in-memory LRU or a Redis-backed cache both satisfy it.
+type PersistedQueryCache interface {
+	// Get returns the query previously registered under hash, if any.
+	Get(hash string) (query string, ok bool)
+	// Put registers query under hash.
+	Put(hash, query string)
+}
+
+// persistedQueryNotFoundCode is the extensions.code value servers use to
+// report that a persisted query's hash wasn't recognized, per the Apollo
+// Automatic Persisted Queries protocol.
+const persistedQueryNotFoundCode = "PersistedQueryNotFound"
+
+// WithPersistedQueries enables Apollo-style Automatic Persisted Queries
+// (APQ) on the client. Every request carries the query's SHA-256 hash. A
+// query whose hash isn't in cache yet is sent with its full text, and its
+// hash is put in cache once that request succeeds; later requests for it
+// send only the hash. If the server reports PersistedQueryNotFound for a
+// hash-only request, the request is retried once with the full query text.
+// No hash-only request is sent for a query that cache doesn't already hold.
+func WithPersistedQueries(cache PersistedQueryCache) ClientOption {
+	return func(c *Client) { c.persistedQueries = cache }
+}
+
+// WithGETForQueries sends queries (never mutations) as HTTP GET requests,
+// with the query, variables and extensions encoded in the URL, so that
+// responses can be cached by a CDN. It's commonly paired with
+// WithPersistedQueries, since a hash-only request is short enough to sit
+// comfortably within URL length limits.
+func WithGETForQueries() ClientOption {
+	return func(c *Client) { c.getForQueries = true }
+}
+
+// doPersisted executes op using Automatic Persisted Queries, retrying with
+// the full query text if the server doesn't yet recognize its hash.
+func (c *Client) doPersisted(ctx context.Context, op operationType, query string, variables map[string]interface{}, v interface{}, opts []RequestOption) error {
+	hash := sha256Hash(query)
+	ext := map[string]interface{}{
+		"persistedQuery": map[string]interface{}{
+			"version":    1,
+			"sha256Hash": hash,
+		},
+	}
+
+	// If cache already confirms the server knows this hash, go straight to
+	// the hash-only request. Otherwise, we don't know whether this hash is
+	// registered anywhere yet, so send the full query text up front rather
+	// than wasting a round trip on an optimistic hash-only probe that's
+	// unlikely to succeed.
+	_, registered := c.persistedQueries.Get(hash)
+	sendQuery := query
+	if registered {
+		sendQuery = ""
+	}
+
+	err := c.roundTrip(ctx, op, sendQuery, ext, variables, v, opts)
+
+	var gqlErrs GraphQLErrors
+	if sendQuery == "" && errors.As(err, &gqlErrs) && gqlErrs.hasCode(persistedQueryNotFoundCode) {
+		err = c.roundTrip(ctx, op, query, ext, variables, v, opts)
+	}
+	if err == nil && !registered {
+		c.persistedQueries.Put(hash, query)
+	}
+	return err
+}
+
+// hasCode reports whether any error in e carries the given extensions code.
+func (e GraphQLErrors) hasCode(code string) bool {
+	for _, ge := range e {
+		if ge.Code() == code {
+			return true
+		}
+	}
+	return false
+}
+
+func sha256Hash(query string) string {
+	sum := sha256.Sum256([]byte(query))
+	return hex.EncodeToString(sum[:])
+}
+
+// newGetRequest builds a cacheable GET request for a query operation, with
+// query, variables and extensions encoded into the URL's query string.
+func (c *Client) newGetRequest(ctx context.Context, query string, ext map[string]interface{}, variables map[string]interface{}) (*http.Request, error) {
+	q := url.Values{}
+	if query != "" {
+		q.Set("query", query)
+	}
+	if len(variables) > 0 {
+		b, err := json.Marshal(variables)
+		if err != nil {
+			return nil, err
+		}
+		q.Set("variables", string(b))
+	}
+	if len(ext) > 0 {
+		b, err := json.Marshal(ext)
+		if err != nil {
+			return nil, err
+		}
+		q.Set("extensions", string(b))
+	}
+
+	return http.NewRequestWithContext(ctx, http.MethodGet, c.url+"?"+q.Encode(), nil)
+}
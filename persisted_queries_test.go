@@ -0,0 +1,88 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+type memPersistedQueryCache struct {
+	mu    sync.Mutex
+	known map[string]string
+}
+
+func (c *memPersistedQueryCache) Get(hash string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	q, ok := c.known[hash]
+	return q, ok
+}
+
+func (c *memPersistedQueryCache) Put(hash, query string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.known == nil {
+		c.known = map[string]string{}
+	}
+	c.known[hash] = query
+}
+
+func TestDoPersistedUsesCacheToSkipProbe(t *testing.T) {
+	var requests []struct {
+		Query      string          `json:"query"`
+		Extensions json.RawMessage `json:"extensions"`
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Query      string          `json:"query"`
+			Extensions json.RawMessage `json:"extensions"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		requests = append(requests, req)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":{"value":1}}`))
+	}))
+	defer srv.Close()
+
+	cache := &memPersistedQueryCache{}
+	c := NewClient(srv.URL, nil, WithPersistedQueries(cache))
+
+	var q struct {
+		Value int `graphql:"value"`
+	}
+
+	// First call: hash unknown to the cache, so the full query text should
+	// be sent immediately (no separate hash-only probe).
+	if err := c.Query(context.Background(), &q, nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(requests) != 1 {
+		t.Fatalf("got %d requests, want 1", len(requests))
+	}
+	if requests[0].Query == "" {
+		t.Error("first request should have included the full query text")
+	}
+
+	// cache.Put should have recorded the hash after the first success.
+	hash := sha256Hash(requests[0].Query)
+	if _, ok := cache.Get(hash); !ok {
+		t.Fatal("cache.Put was not called after a successful first request")
+	}
+
+	// Second call: cache now confirms the hash is registered, so only the
+	// hash-only request should be sent, and Put should not be called again.
+	requests = nil
+	if err := c.Query(context.Background(), &q, nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(requests) != 1 {
+		t.Fatalf("got %d requests, want 1", len(requests))
+	}
+	if requests[0].Query != "" {
+		t.Error("second request should have been hash-only, cache already confirmed registration")
+	}
+}
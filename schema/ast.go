@@ -0,0 +1,65 @@
+package schema
+
+// Schema is a parsed GraphQL SDL document.
+type Schema struct {
+	Query        string
+	Mutation     string
+	Subscription string
+
+	Types map[string]*TypeDef
+}
+
+// TypeKind distinguishes the different kinds of type definitions a Schema
+// can contain.
+type TypeKind int
+
+const (
+	KindObject TypeKind = iota
+	KindInput
+	KindInterface
+	KindEnum
+	KindScalar
+	KindUnion
+)
+
+// TypeDef is a single named type definition from the schema: an object,
+// input, interface, enum, scalar or union.
+type TypeDef struct {
+	Name        string
+	Kind        TypeKind
+	Description string
+
+	Fields     []*FieldDef // Object, input and interface types.
+	EnumValues []string    // Enum types.
+	UnionTypes []string    // Union types, by member type name.
+	Implements []string    // Object types, by interface name.
+}
+
+// FieldDef is a single field of an object, input or interface type.
+type FieldDef struct {
+	Name        string
+	Description string
+	Type        *TypeRef
+	Args        []*FieldDef // Non-nil only for object/interface fields that declare arguments.
+}
+
+// TypeRef is a GraphQL type reference, such as `[String!]!`.
+type TypeRef struct {
+	Name    string // Empty when List is non-nil.
+	List    *TypeRef
+	NonNull bool
+}
+
+// String renders t using GraphQL type syntax, e.g. "[String!]!".
+func (t *TypeRef) String() string {
+	var s string
+	if t.List != nil {
+		s = "[" + t.List.String() + "]"
+	} else {
+		s = t.Name
+	}
+	if t.NonNull {
+		s += "!"
+	}
+	return s
+}
@@ -0,0 +1,43 @@
+package schema
+
+import (
+	"encoding/json"
+	"os"
+)
+
+// Config customizes graphqlgen's code generation: the output package name
+// and mappings from custom GraphQL scalar names to Go types.
+type Config struct {
+	Package string                  `json:"package"`
+	Scalars map[string]ScalarConfig `json:"scalars"`
+}
+
+// ScalarConfig maps a single custom scalar to the Go type that represents
+// it, and the import path that type comes from, if not a predeclared type.
+type ScalarConfig struct {
+	Type   string `json:"type"`
+	Import string `json:"import,omitempty"`
+}
+
+// LoadConfig reads a JSON config file written for graphqlgen, e.g.:
+//
+//	{
+//	  "package": "genqueries",
+//	  "scalars": {
+//	    "DateTime": {"type": "time.Time", "import": "time"}
+//	  }
+//	}
+func LoadConfig(path string) (*Config, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	cfg := &Config{Package: "main"}
+	if err := json.Unmarshal(b, cfg); err != nil {
+		return nil, err
+	}
+	if cfg.Scalars == nil {
+		cfg.Scalars = map[string]ScalarConfig{}
+	}
+	return cfg, nil
+}
@@ -0,0 +1,186 @@
+// Package schema parses GraphQL SDL schema documents and .graphql operation
+// documents well enough to drive code generation; see cmd/graphqlgen.
+package schema
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenName
+	tokenInt
+	tokenFloat
+	tokenString
+	tokenPunct
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	line int
+}
+
+// lexer tokenizes a GraphQL document (SDL or operation) into tokens,
+// skipping comments, commas and insignificant whitespace per the GraphQL
+// lexical grammar.
+type lexer struct {
+	src  []rune
+	pos  int
+	line int
+}
+
+func newLexer(src string) *lexer {
+	return &lexer{src: []rune(src), line: 1}
+}
+
+func (l *lexer) peekRune() (rune, bool) {
+	if l.pos >= len(l.src) {
+		return 0, false
+	}
+	return l.src[l.pos], true
+}
+
+func (l *lexer) next() token {
+	l.skipIgnored()
+	r, ok := l.peekRune()
+	if !ok {
+		return token{kind: tokenEOF, line: l.line}
+	}
+
+	switch {
+	case r == '"':
+		return l.lexString()
+	case r == '-' || unicode.IsDigit(r):
+		return l.lexNumber()
+	case unicode.IsLetter(r) || r == '_':
+		return l.lexName()
+	case strings.ContainsRune("{}()[]:!=@|&$...", r):
+		return l.lexPunct()
+	default:
+		panic(fmt.Sprintf("schema: unexpected character %q at line %d", r, l.line))
+	}
+}
+
+func (l *lexer) skipIgnored() {
+	for {
+		r, ok := l.peekRune()
+		if !ok {
+			return
+		}
+		switch {
+		case r == '\n':
+			l.line++
+			l.pos++
+		case unicode.IsSpace(r) || r == ',':
+			l.pos++
+		case r == '#':
+			for {
+				r, ok := l.peekRune()
+				if !ok || r == '\n' {
+					break
+				}
+				l.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+func (l *lexer) lexName() token {
+	start := l.pos
+	for {
+		r, ok := l.peekRune()
+		if !ok || !(unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_') {
+			break
+		}
+		l.pos++
+	}
+	return token{kind: tokenName, text: string(l.src[start:l.pos]), line: l.line}
+}
+
+func (l *lexer) lexNumber() token {
+	start := l.pos
+	isFloat := false
+	if r, _ := l.peekRune(); r == '-' {
+		l.pos++
+	}
+	for {
+		r, ok := l.peekRune()
+		if !ok {
+			break
+		}
+		switch {
+		case unicode.IsDigit(r):
+			l.pos++
+		case r == '.' || r == 'e' || r == 'E':
+			isFloat = true
+			l.pos++
+		default:
+			goto done
+		}
+	}
+done:
+	kind := tokenInt
+	if isFloat {
+		kind = tokenFloat
+	}
+	return token{kind: kind, text: string(l.src[start:l.pos]), line: l.line}
+}
+
+func (l *lexer) lexString() token {
+	// Treat a leading `"""` as the start of a block string; otherwise a
+	// regular quoted string. Escape sequences are passed through verbatim:
+	// the token's text is the source between the quotes, undecoded.
+	if strings.HasPrefix(string(l.src[l.pos:min(l.pos+3, len(l.src))]), `"""`) {
+		l.pos += 3
+		start := l.pos
+		for !strings.HasPrefix(string(l.src[l.pos:min(l.pos+3, len(l.src))]), `"""`) && l.pos < len(l.src) {
+			if l.src[l.pos] == '\n' {
+				l.line++
+			}
+			l.pos++
+		}
+		text := string(l.src[start:l.pos])
+		l.pos += 3
+		return token{kind: tokenString, text: text, line: l.line}
+	}
+
+	l.pos++ // Opening quote.
+	start := l.pos
+	for {
+		r, ok := l.peekRune()
+		if !ok || r == '"' {
+			break
+		}
+		if r == '\\' {
+			l.pos++
+		}
+		l.pos++
+	}
+	text := string(l.src[start:l.pos])
+	l.pos++ // Closing quote.
+	return token{kind: tokenString, text: text, line: l.line}
+}
+
+func (l *lexer) lexPunct() token {
+	if l.pos+3 <= len(l.src) && string(l.src[l.pos:l.pos+3]) == "..." {
+		l.pos += 3
+		return token{kind: tokenPunct, text: "...", line: l.line}
+	}
+	r := l.src[l.pos]
+	l.pos++
+	return token{kind: tokenPunct, text: string(r), line: l.line}
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
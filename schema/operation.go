@@ -0,0 +1,194 @@
+package schema
+
+import "fmt"
+
+// OperationKind is the kind of a top-level operation in an operation
+// document: query, mutation or subscription.
+type OperationKind int
+
+const (
+	OpQuery OperationKind = iota
+	OpMutation
+	OpSubscription
+)
+
+// Operation is a single named query/mutation/subscription parsed from a
+// .graphql operation document.
+type Operation struct {
+	Name      string
+	Kind      OperationKind
+	Variables []*Variable
+	Selection []*Selection
+}
+
+// Variable is one of an operation's `($name: Type)` declarations.
+type Variable struct {
+	Name string
+	Type *TypeRef
+}
+
+// Selection is a single field (or fragment spread) within a selection set.
+type Selection struct {
+	Alias     string
+	Name      string // Field name, or the fragment name for FragmentSpread.
+	Args      map[string]ArgValue
+	Selection []*Selection // Nested selection set, if any.
+
+	FragmentSpread bool
+}
+
+// ArgValue is a field argument's value: either a literal (Raw, already
+// GraphQL-syntax text) or a reference to an operation variable (Variable).
+type ArgValue struct {
+	Variable string
+	Raw      string
+}
+
+// Fragment is a named `fragment F on T { ... }` definition.
+type Fragment struct {
+	Name      string
+	On        string
+	Selection []*Selection
+}
+
+// Document is a parsed .graphql operation document: the operations and
+// fragments it defines.
+type Document struct {
+	Operations []*Operation
+	Fragments  map[string]*Fragment
+}
+
+// ParseDocument parses a .graphql file containing one or more
+// query/mutation/subscription operations and fragment definitions.
+func ParseDocument(src string) (doc *Document, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("schema: %v", r)
+		}
+	}()
+
+	p := &parser{lex: newLexer(src)}
+	p.advance()
+
+	doc = &Document{Fragments: map[string]*Fragment{}}
+	for p.tok.kind != tokenEOF {
+		switch {
+		case p.isName("fragment"):
+			f := p.parseFragment()
+			doc.Fragments[f.Name] = f
+		default:
+			doc.Operations = append(doc.Operations, p.parseOperation())
+		}
+	}
+	return doc, nil
+}
+
+func (p *parser) parseOperation() *Operation {
+	op := &Operation{Kind: OpQuery}
+	switch {
+	case p.isName("query"):
+		p.advance()
+	case p.isName("mutation"):
+		op.Kind = OpMutation
+		p.advance()
+	case p.isName("subscription"):
+		op.Kind = OpSubscription
+		p.advance()
+	}
+
+	if p.tok.kind == tokenName {
+		op.Name = p.tok.text
+		p.advance()
+	}
+
+	if p.isPunct("(") {
+		p.advance()
+		for !p.isPunct(")") {
+			p.expectPunct("$")
+			name := p.expectName()
+			p.expectPunct(":")
+			typ := p.parseTypeRef()
+			if p.isPunct("=") {
+				p.advance()
+				p.skipValue()
+			}
+			p.skipDirectives()
+			op.Variables = append(op.Variables, &Variable{Name: name, Type: typ})
+		}
+		p.expectPunct(")")
+	}
+	p.skipDirectives()
+	op.Selection = p.parseSelectionSet()
+	return op
+}
+
+func (p *parser) parseFragment() *Fragment {
+	p.advance() // "fragment"
+	f := &Fragment{Name: p.expectName()}
+	if !p.isName("on") {
+		panic(fmt.Sprintf("line %d: expected \"on\", got %q", p.tok.line, p.tok.text))
+	}
+	p.advance()
+	f.On = p.expectName()
+	p.skipDirectives()
+	f.Selection = p.parseSelectionSet()
+	return f
+}
+
+func (p *parser) parseSelectionSet() []*Selection {
+	p.expectPunct("{")
+	var sels []*Selection
+	for !p.isPunct("}") {
+		sels = append(sels, p.parseSelection())
+	}
+	p.expectPunct("}")
+	return sels
+}
+
+func (p *parser) parseSelection() *Selection {
+	if p.isPunct("...") {
+		p.advance()
+		if p.isName("on") { // Inline fragment: flatten its fields into the parent.
+			p.advance()
+			p.expectName()
+			p.skipDirectives()
+			return &Selection{Selection: p.parseSelectionSet()}
+		}
+		name := p.expectName()
+		p.skipDirectives()
+		return &Selection{Name: name, FragmentSpread: true}
+	}
+
+	first := p.expectName()
+	sel := &Selection{Name: first}
+	if p.isPunct(":") {
+		p.advance()
+		sel.Alias = first
+		sel.Name = p.expectName()
+	}
+
+	if p.isPunct("(") {
+		p.advance()
+		sel.Args = map[string]ArgValue{}
+		for !p.isPunct(")") {
+			argName := p.expectName()
+			p.expectPunct(":")
+			sel.Args[argName] = p.parseArgValue()
+		}
+		p.expectPunct(")")
+	}
+	p.skipDirectives()
+
+	if p.isPunct("{") {
+		sel.Selection = p.parseSelectionSet()
+	}
+	return sel
+}
+
+func (p *parser) parseArgValue() ArgValue {
+	if p.isPunct("$") {
+		p.advance()
+		return ArgValue{Variable: p.expectName()}
+	}
+	return ArgValue{Raw: p.parseValueRaw()}
+}
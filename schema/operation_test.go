@@ -0,0 +1,33 @@
+package schema
+
+import "testing"
+
+func TestParseArgValueCapturesFullListAndObjectLiterals(t *testing.T) {
+	doc, err := ParseDocument(`query Foo { items(tags: ["a", "b"], filter: {name: "x", active: true}) { id } }`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sel := doc.Operations[0].Selection[0]
+
+	if got, want := sel.Args["tags"].Raw, `["a", "b"]`; got != want {
+		t.Errorf("tags.Raw = %q, want %q", got, want)
+	}
+	if got, want := sel.Args["filter"].Raw, `{name: "x", active: true}`; got != want {
+		t.Errorf("filter.Raw = %q, want %q", got, want)
+	}
+}
+
+func TestParseArgValueScalarAndVariable(t *testing.T) {
+	doc, err := ParseDocument(`query Foo($limit: Int!) { items(first: $limit, offset: 5) { id } }`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sel := doc.Operations[0].Selection[0]
+
+	if got := sel.Args["first"].Variable; got != "limit" {
+		t.Errorf("first.Variable = %q, want %q", got, "limit")
+	}
+	if got, want := sel.Args["offset"].Raw, "5"; got != want {
+		t.Errorf("offset.Raw = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,334 @@
+package schema
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Parse parses a GraphQL SDL document (type/input/enum/scalar/interface/union
+// and schema definitions) into a Schema.
+func Parse(sdl string) (s *Schema, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("schema: %v", r)
+		}
+	}()
+
+	p := &parser{lex: newLexer(sdl)}
+	p.advance()
+
+	s = &Schema{
+		// Per the spec, root operation types default to the types literally
+		// named Query/Mutation/Subscription unless a schema{} block overrides
+		// them; see parseSchemaDefinition.
+		Query:        "Query",
+		Mutation:     "Mutation",
+		Subscription: "Subscription",
+		Types:        map[string]*TypeDef{},
+	}
+	for p.tok.kind != tokenEOF {
+		p.parseDefinition(s)
+	}
+	return s, nil
+}
+
+type parser struct {
+	lex *lexer
+	tok token
+}
+
+func (p *parser) advance() { p.tok = p.lex.next() }
+
+func (p *parser) expectPunct(text string) {
+	if p.tok.kind != tokenPunct || p.tok.text != text {
+		panic(fmt.Sprintf("line %d: expected %q, got %q", p.tok.line, text, p.tok.text))
+	}
+	p.advance()
+}
+
+func (p *parser) expectName() string {
+	if p.tok.kind != tokenName {
+		panic(fmt.Sprintf("line %d: expected name, got %q", p.tok.line, p.tok.text))
+	}
+	name := p.tok.text
+	p.advance()
+	return name
+}
+
+func (p *parser) isPunct(text string) bool {
+	return p.tok.kind == tokenPunct && p.tok.text == text
+}
+
+func (p *parser) isName(text string) bool {
+	return p.tok.kind == tokenName && p.tok.text == text
+}
+
+func (p *parser) parseDefinition(s *Schema) {
+	var description string
+	if p.tok.kind == tokenString {
+		description = p.tok.text
+		p.advance()
+	}
+
+	switch {
+	case p.isName("schema"):
+		p.parseSchemaDefinition(s)
+	case p.isName("type"):
+		p.parseTypeDef(s, KindObject, description)
+	case p.isName("input"):
+		p.parseTypeDef(s, KindInput, description)
+	case p.isName("interface"):
+		p.parseTypeDef(s, KindInterface, description)
+	case p.isName("enum"):
+		p.parseEnumDef(s, description)
+	case p.isName("scalar"):
+		p.advance()
+		name := p.expectName()
+		s.Types[name] = &TypeDef{Name: name, Kind: KindScalar, Description: description}
+	case p.isName("union"):
+		p.parseUnionDef(s, description)
+	case p.isName("directive"):
+		p.skipDirectiveDef()
+	case p.isName("extend"):
+		p.advance()
+		p.parseDefinition(s) // Treat `extend type Foo { ... }` as a plain definition.
+	default:
+		panic(fmt.Sprintf("line %d: unexpected token %q", p.tok.line, p.tok.text))
+	}
+}
+
+func (p *parser) parseSchemaDefinition(s *Schema) {
+	p.advance() // "schema"
+	p.skipDirectives()
+	p.expectPunct("{")
+	for !p.isPunct("}") {
+		op := p.expectName()
+		p.expectPunct(":")
+		typeName := p.expectName()
+		switch op {
+		case "query":
+			s.Query = typeName
+		case "mutation":
+			s.Mutation = typeName
+		case "subscription":
+			s.Subscription = typeName
+		}
+	}
+	p.expectPunct("}")
+}
+
+func (p *parser) parseTypeDef(s *Schema, kind TypeKind, description string) {
+	p.advance() // "type" / "input" / "interface"
+	name := p.expectName()
+	def := &TypeDef{Name: name, Kind: kind, Description: description}
+
+	if p.isName("implements") {
+		p.advance()
+		for {
+			def.Implements = append(def.Implements, p.expectName())
+			if p.isPunct("&") {
+				p.advance()
+				continue
+			}
+			break
+		}
+	}
+	p.skipDirectives()
+
+	if p.isPunct("{") {
+		p.expectPunct("{")
+		for !p.isPunct("}") {
+			def.Fields = append(def.Fields, p.parseFieldDef())
+		}
+		p.expectPunct("}")
+	}
+	s.Types[name] = def
+}
+
+func (p *parser) parseFieldDef() *FieldDef {
+	var description string
+	if p.tok.kind == tokenString {
+		description = p.tok.text
+		p.advance()
+	}
+
+	name := p.expectName()
+	f := &FieldDef{Name: name, Description: description}
+
+	if p.isPunct("(") {
+		p.advance()
+		for !p.isPunct(")") {
+			f.Args = append(f.Args, p.parseFieldDef())
+		}
+		p.expectPunct(")")
+	}
+
+	p.expectPunct(":")
+	f.Type = p.parseTypeRef()
+
+	if p.isPunct("=") { // Default value, only relevant to input/arg fields.
+		p.advance()
+		p.skipValue()
+	}
+	p.skipDirectives()
+	return f
+}
+
+func (p *parser) parseTypeRef() *TypeRef {
+	var t *TypeRef
+	if p.isPunct("[") {
+		p.advance()
+		inner := p.parseTypeRef()
+		p.expectPunct("]")
+		t = &TypeRef{List: inner}
+	} else {
+		t = &TypeRef{Name: p.expectName()}
+	}
+	if p.isPunct("!") {
+		p.advance()
+		t.NonNull = true
+	}
+	return t
+}
+
+func (p *parser) parseEnumDef(s *Schema, description string) {
+	p.advance() // "enum"
+	name := p.expectName()
+	p.skipDirectives()
+	def := &TypeDef{Name: name, Kind: KindEnum, Description: description}
+	p.expectPunct("{")
+	for !p.isPunct("}") {
+		if p.tok.kind == tokenString {
+			p.advance() // Value description.
+		}
+		def.EnumValues = append(def.EnumValues, p.expectName())
+		p.skipDirectives()
+	}
+	p.expectPunct("}")
+	s.Types[name] = def
+}
+
+func (p *parser) parseUnionDef(s *Schema, description string) {
+	p.advance() // "union"
+	name := p.expectName()
+	p.skipDirectives()
+	def := &TypeDef{Name: name, Kind: KindUnion, Description: description}
+	p.expectPunct("=")
+	for {
+		def.UnionTypes = append(def.UnionTypes, p.expectName())
+		if p.isPunct("|") {
+			p.advance()
+			continue
+		}
+		break
+	}
+	s.Types[name] = def
+}
+
+// skipDirectiveDef discards a `directive @foo(...) on FIELD` definition; the
+// generator has no use for directive definitions themselves.
+func (p *parser) skipDirectiveDef() {
+	p.advance() // "directive"
+	p.expectPunct("@")
+	p.expectName()
+	if p.isPunct("(") {
+		p.advance()
+		depth := 1
+		for depth > 0 {
+			switch {
+			case p.isPunct("("):
+				depth++
+			case p.isPunct(")"):
+				depth--
+			}
+			p.advance()
+		}
+	}
+	for p.tok.kind == tokenName { // "repeatable", "on", location names joined by "|".
+		p.advance()
+		if p.isPunct("|") {
+			p.advance()
+		}
+	}
+}
+
+func (p *parser) skipDirectives() {
+	for p.isPunct("@") {
+		p.advance()
+		p.expectName()
+		if p.isPunct("(") {
+			p.advance()
+			for !p.isPunct(")") {
+				p.expectName()
+				p.expectPunct(":")
+				p.skipValue()
+			}
+			p.expectPunct(")")
+		}
+	}
+}
+
+// skipValue discards a single default/argument value: a literal, list or
+// input object. The generator only needs to know where the value ends.
+func (p *parser) skipValue() {
+	switch {
+	case p.isPunct("["):
+		p.advance()
+		for !p.isPunct("]") {
+			p.skipValue()
+		}
+		p.expectPunct("]")
+	case p.isPunct("{"):
+		p.advance()
+		for !p.isPunct("}") {
+			p.expectName()
+			p.expectPunct(":")
+			p.skipValue()
+		}
+		p.expectPunct("}")
+	case p.isPunct("$"):
+		p.advance()
+		p.expectName()
+	default:
+		p.advance()
+	}
+}
+
+// parseValueRaw parses a single argument value the same way skipValue does,
+// but renders it back out as GraphQL source text instead of discarding it,
+// so callers that need the full literal (e.g. a field argument) get more
+// than just its first token.
+func (p *parser) parseValueRaw() string {
+	switch {
+	case p.isPunct("["):
+		p.advance()
+		var elems []string
+		for !p.isPunct("]") {
+			elems = append(elems, p.parseValueRaw())
+		}
+		p.expectPunct("]")
+		return "[" + strings.Join(elems, ", ") + "]"
+	case p.isPunct("{"):
+		p.advance()
+		var fields []string
+		for !p.isPunct("}") {
+			name := p.expectName()
+			p.expectPunct(":")
+			fields = append(fields, name+": "+p.parseValueRaw())
+		}
+		p.expectPunct("}")
+		return "{" + strings.Join(fields, ", ") + "}"
+	case p.isPunct("$"):
+		p.advance()
+		return "$" + p.expectName()
+	case p.tok.kind == tokenString:
+		text := strconv.Quote(p.tok.text)
+		p.advance()
+		return text
+	default:
+		text := p.tok.text
+		p.advance()
+		return text
+	}
+}
@@ -0,0 +1,40 @@
+package schema
+
+import "testing"
+
+func TestParseDefaultsRootTypes(t *testing.T) {
+	s, err := Parse(`
+		type Query { hello: String }
+		type Mutation { createThing(name: String!): String }
+		type Subscription { onThing: String }
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Query != "Query" {
+		t.Errorf("Query = %q, want %q", s.Query, "Query")
+	}
+	if s.Mutation != "Mutation" {
+		t.Errorf("Mutation = %q, want %q (implicit default per spec)", s.Mutation, "Mutation")
+	}
+	if s.Subscription != "Subscription" {
+		t.Errorf("Subscription = %q, want %q (implicit default per spec)", s.Subscription, "Subscription")
+	}
+}
+
+func TestParseExplicitSchemaBlockOverridesDefaults(t *testing.T) {
+	s, err := Parse(`
+		schema { query: Q, mutation: M }
+		type Q { hello: String }
+		type M { createThing: String }
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Query != "Q" {
+		t.Errorf("Query = %q, want %q", s.Query, "Q")
+	}
+	if s.Mutation != "M" {
+		t.Errorf("Mutation = %q, want %q", s.Mutation, "M")
+	}
+}
@@ -0,0 +1,396 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/shurcooL/graphql/internal/jsonutil"
+)
+
+// Subscription subprotocols supported by Subscribe, negotiated via the
+// Sec-WebSocket-Protocol header.
+const (
+	subprotocolGraphQLWS          = "graphql-ws"           // Apollo's legacy subprotocol.
+	subprotocolGraphQLTransportWS = "graphql-transport-ws" // The current subprotocol.
+)
+
+// wsSupportedSubprotocols lists the subprotocols offered to the server, most
+// preferred first.
+var wsSupportedSubprotocols = []string{subprotocolGraphQLTransportWS, subprotocolGraphQLWS}
+
+// Subscription represents an open GraphQL subscription started by Client.Subscribe.
+// It must be closed with Close once no longer needed.
+type Subscription struct {
+	conn        *websocket.Conn
+	subprotocol string
+	id          string
+
+	outType reflect.Type // Element type of the struct passed to Subscribe.
+
+	updates chan interface{}
+	errs    chan error
+
+	closeOnce sync.Once
+	closeErr  error
+	done      chan struct{}
+}
+
+// Subscribe opens a GraphQL subscription over WebSocket, with a subscription
+// derived from s, and streams results from the server into fresh copies of s.
+// s should be a pointer to struct that corresponds to the GraphQL schema.
+//
+// Subscribe negotiates either the legacy graphql-ws subprotocol or the
+// current graphql-transport-ws subprotocol, depending on what the server
+// supports. The returned Subscription must be closed with Close when no
+// longer needed.
+func (c *Client) Subscribe(ctx context.Context, s interface{}, variables map[string]interface{}, opts ...RequestOption) (*Subscription, error) {
+	query := constructSubscription(s, variables)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var allOpts []RequestOption
+	allOpts = append(allOpts, c.requestOptions...)
+	allOpts = append(allOpts, opts...)
+	for _, opt := range allOpts {
+		if err := opt(req); err != nil {
+			return nil, &OptionError{Err: err}
+		}
+	}
+	initPayload, _ := req.Context().Value(connInitPayloadKey{}).(interface{})
+
+	dialer := websocket.Dialer{Subprotocols: wsSupportedSubprotocols}
+	conn, resp, err := dialer.DialContext(ctx, wsURL(c.url), req.Header)
+	if err != nil {
+		return nil, fmt.Errorf("dialing subscription websocket: %w", err)
+	}
+
+	subprotocol := subprotocolGraphQLTransportWS
+	if resp != nil && resp.Header.Get("Sec-WebSocket-Protocol") == subprotocolGraphQLWS {
+		subprotocol = subprotocolGraphQLWS
+	}
+
+	sub := &Subscription{
+		conn:        conn,
+		subprotocol: subprotocol,
+		id:          "1",
+		outType:     reflect.TypeOf(s).Elem(),
+		updates:     make(chan interface{}, 1),
+		errs:        make(chan error, 1),
+		done:        make(chan struct{}),
+	}
+
+	if err := sub.handshake(ctx, initPayload); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if err := sub.sendStart(variables, query); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	go sub.readLoop()
+
+	return sub, nil
+}
+
+// connInitPayloadKey is the context key WithConnectionInitPayload stashes
+// its payload under, for Subscribe to read back off the request it was
+// applied to.
+type connInitPayloadKey struct{}
+
+// WithConnectionInitPayload attaches a payload to be sent as the `payload`
+// of a subscription's connection_init frame, e.g. for servers that expect
+// authentication there rather than in the HTTP upgrade headers. It has no
+// effect on Query, Mutate, or non-Subscribe requests.
+func WithConnectionInitPayload(payload interface{}) RequestOption {
+	return func(req *http.Request) error {
+		*req = *req.WithContext(context.WithValue(req.Context(), connInitPayloadKey{}, payload))
+		return nil
+	}
+}
+
+// connectionAckTimeout bounds how long Subscribe waits for the server's
+// connection_ack before giving up.
+const connectionAckTimeout = 10 * time.Second
+
+// wsURL rewrites a http(s):// GraphQL endpoint into its ws(s):// equivalent.
+func wsURL(url string) string {
+	switch {
+	case len(url) >= 8 && url[:8] == "https://":
+		return "wss://" + url[8:]
+	case len(url) >= 7 && url[:7] == "http://":
+		return "ws://" + url[7:]
+	default:
+		return url
+	}
+}
+
+// handshake sends connection_init (with initPayload, if any) and blocks
+// until the server replies with connection_ack, as required by both the
+// graphql-ws and graphql-transport-ws specs before any other message may be
+// sent.
+func (s *Subscription) handshake(ctx context.Context, initPayload interface{}) error {
+	initFrame := wsFrame{Type: "connection_init"}
+	if initPayload != nil {
+		b, err := json.Marshal(initPayload)
+		if err != nil {
+			return err
+		}
+		initFrame.Payload = b
+	}
+	if err := s.writeJSON(initFrame); err != nil {
+		return err
+	}
+
+	hctx, cancel := context.WithTimeout(ctx, connectionAckTimeout)
+	defer cancel()
+
+	type result struct {
+		frame wsFrame
+		err   error
+	}
+	res := make(chan result, 1)
+	go func() {
+		_, b, err := s.conn.ReadMessage()
+		if err != nil {
+			res <- result{err: err}
+			return
+		}
+		var frame wsFrame
+		if err := json.Unmarshal(b, &frame); err != nil {
+			res <- result{err: err}
+			return
+		}
+		res <- result{frame: frame}
+	}()
+
+	select {
+	case <-hctx.Done():
+		return fmt.Errorf("subscription: timed out waiting for connection_ack: %w", hctx.Err())
+	case r := <-res:
+		if r.err != nil {
+			return fmt.Errorf("subscription: reading connection_ack: %w", r.err)
+		}
+		switch r.frame.Type {
+		case "connection_ack":
+			return nil
+		case "connection_error":
+			return fmt.Errorf("subscription: connection_error: %s", r.frame.Payload)
+		default:
+			return fmt.Errorf("subscription: expected connection_ack, got %q", r.frame.Type)
+		}
+	}
+}
+
+// sendStart sends the subscribe/start frame appropriate for the negotiated
+// subprotocol. It must only be called after handshake has completed.
+func (s *Subscription) sendStart(variables map[string]interface{}, query string) error {
+	payload, err := subscribePayload(query, variables)
+	if err != nil {
+		return err
+	}
+
+	if s.subprotocol == subprotocolGraphQLWS {
+		return s.writeJSON(wsFrame{ID: s.id, Type: "start", Payload: payload})
+	}
+	return s.writeJSON(wsFrame{ID: s.id, Type: "subscribe", Payload: payload})
+}
+
+// subscribePayload encodes the `payload` of a subscribe/start frame.
+func subscribePayload(query string, variables map[string]interface{}) (json.RawMessage, error) {
+	return json.Marshal(struct {
+		Query     string                 `json:"query"`
+		Variables map[string]interface{} `json:"variables,omitempty"`
+	}{Query: query, Variables: variables})
+}
+
+// wsFrame is a protocol frame shared by both the graphql-ws and
+// graphql-transport-ws subprotocols; only the fields relevant to a given
+// message type are populated.
+type wsFrame struct {
+	ID      string          `json:"id,omitempty"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+func (s *Subscription) writeJSON(v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return s.conn.WriteMessage(websocket.TextMessage, b)
+}
+
+// readLoop dispatches incoming frames until the connection is closed.
+func (s *Subscription) readLoop() {
+	defer close(s.done)
+	for {
+		_, b, err := s.conn.ReadMessage()
+		if err != nil {
+			select {
+			case s.errs <- err:
+			default:
+			}
+			return
+		}
+
+		var frame struct {
+			ID      string          `json:"id"`
+			Type    string          `json:"type"`
+			Payload json.RawMessage `json:"payload"`
+		}
+		if err := json.Unmarshal(b, &frame); err != nil {
+			select {
+			case s.errs <- err:
+			default:
+			}
+			return
+		}
+
+		switch frame.Type {
+		case "ping":
+			_ = s.writeJSON(wsFrame{Type: "pong"})
+		case "ka": // graphql-ws keep-alive.
+		case "connection_ack":
+		case "data", "next":
+			var data struct {
+				Data   *json.RawMessage `json:"data"`
+				Errors GraphQLErrors    `json:"errors"`
+			}
+			if err := json.Unmarshal(frame.Payload, &data); err != nil {
+				select {
+				case s.errs <- err:
+				default:
+				}
+				continue
+			}
+			if len(data.Errors) > 0 {
+				select {
+				case s.errs <- data.Errors:
+				default:
+				}
+				continue
+			}
+			if data.Data == nil {
+				continue
+			}
+			out := reflect.New(s.outType)
+			if err := jsonutil.UnmarshalGraphQL(*data.Data, out.Interface()); err != nil {
+				select {
+				case s.errs <- err:
+				default:
+				}
+				continue
+			}
+			select {
+			case s.updates <- out.Interface():
+			case <-s.done:
+				return
+			}
+		case "error":
+			var errs GraphQLErrors
+			if err := json.Unmarshal(frame.Payload, &errs); err != nil {
+				select {
+				case s.errs <- err:
+				default:
+				}
+			} else {
+				select {
+				case s.errs <- errs:
+				default:
+				}
+			}
+		case "complete":
+			return
+		case "connection_error":
+			select {
+			case s.errs <- fmt.Errorf("subscription: connection_error: %s", frame.Payload):
+			default:
+			}
+			return
+		}
+	}
+}
+
+// Next blocks until the next event arrives, decodes it into v (a pointer to
+// the same struct type originally passed to Subscribe), and returns. It
+// returns io.EOF once the server has sent a "complete" frame; if the
+// connection is closed or a read from it fails first, it returns that error.
+func (s *Subscription) Next(ctx context.Context, v interface{}) error {
+	// readLoop sends a terminal error to s.errs and then closes s.done, in
+	// that order, so both channels can be simultaneously ready by the time
+	// Next observes them and select would otherwise pick between them at
+	// random. Draining s.errs first, non-blocking, makes sure a real error
+	// always wins over the plain io.EOF that <-s.done implies.
+	select {
+	case err := <-s.errs:
+		return err
+	default:
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case err := <-s.errs:
+		return err
+	case out, ok := <-s.updates:
+		if !ok {
+			return io.EOF
+		}
+		reflect.ValueOf(v).Elem().Set(reflect.ValueOf(out).Elem())
+		return nil
+	case <-s.done:
+		select {
+		case err := <-s.errs:
+			return err
+		default:
+		}
+		select {
+		case out, ok := <-s.updates:
+			if ok {
+				reflect.ValueOf(v).Elem().Set(reflect.ValueOf(out).Elem())
+				return nil
+			}
+		default:
+		}
+		return io.EOF
+	}
+}
+
+// Close terminates the subscription and closes the underlying WebSocket
+// connection. It is safe to call Close multiple times.
+func (s *Subscription) Close() error {
+	s.closeOnce.Do(func() {
+		if s.subprotocol == subprotocolGraphQLWS {
+			_ = s.writeJSON(wsFrame{ID: s.id, Type: "stop"})
+			_ = s.writeJSON(wsFrame{Type: "connection_terminate"})
+		} else {
+			_ = s.writeJSON(wsFrame{ID: s.id, Type: "complete"})
+		}
+		_ = s.conn.WriteControl(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""),
+			time.Now().Add(time.Second))
+		s.closeErr = s.conn.Close()
+	})
+	return s.closeErr
+}
+
+// constructSubscription constructs and encodes a GraphQL subscription string
+// from s and variables, mirroring constructQuery.
+func constructSubscription(s interface{}, variables map[string]interface{}) string {
+	query := query(s)
+	if len(variables) > 0 {
+		return "subscription(" + queryArguments(variables) + ")" + query
+	}
+	return "subscription" + query
+}
@@ -0,0 +1,48 @@
+package graphql
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestSubscribePayload(t *testing.T) {
+	payload, err := subscribePayload("subscription{comments{id}}", map[string]interface{}{"limit": 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got struct {
+		Query     string                 `json:"query"`
+		Variables map[string]interface{} `json:"variables"`
+	}
+	if err := json.Unmarshal(payload, &got); err != nil {
+		t.Fatalf("payload isn't valid JSON: %v (got %s)", err, payload)
+	}
+	if got.Query != "subscription{comments{id}}" {
+		t.Errorf("Query = %q, want %q", got.Query, "subscription{comments{id}}")
+	}
+	if got.Variables["limit"] != float64(5) {
+		t.Errorf("Variables[limit] = %v, want 5", got.Variables["limit"])
+	}
+}
+
+func TestWithConnectionInitPayload(t *testing.T) {
+	req, err := http.NewRequest(http.MethodGet, "http://example.com/graphql", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opt := WithConnectionInitPayload(map[string]string{"authToken": "secret"})
+	if err := opt(req); err != nil {
+		t.Fatal(err)
+	}
+
+	got := req.Context().Value(connInitPayloadKey{})
+	if got == nil {
+		t.Fatal("connection_init payload was not attached to the request context")
+	}
+	if m, ok := got.(map[string]string); !ok || m["authToken"] != "secret" {
+		t.Errorf("payload = %#v, want map[authToken:secret]", got)
+	}
+}
@@ -0,0 +1,161 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gorilla/websocket"
+)
+
+// dialFakeSubscriptionServer starts a fake WebSocket server running handle
+// for exactly one connection, dials it the same way Client.Subscribe does,
+// and returns the negotiated Subscription's subprotocol alongside the raw
+// *websocket.Conn so tests can drive handshake/readLoop/Next/Close directly
+// without going through Client.Subscribe.
+func dialFakeSubscriptionServer(t *testing.T, handle func(conn *websocket.Conn)) (conn *websocket.Conn, subprotocol string, done <-chan struct{}) {
+	t.Helper()
+
+	serverDone := make(chan struct{})
+	upgrader := websocket.Upgrader{Subprotocols: wsSupportedSubprotocols}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer close(serverDone)
+		c, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("server upgrade: %v", err)
+			return
+		}
+		defer c.Close()
+		handle(c)
+	}))
+	t.Cleanup(srv.Close)
+
+	dialer := websocket.Dialer{Subprotocols: wsSupportedSubprotocols}
+	conn, resp, err := dialer.Dial(wsURL(srv.URL), nil)
+	if err != nil {
+		t.Fatalf("dialing fake subscription server: %v", err)
+	}
+
+	subprotocol = subprotocolGraphQLTransportWS
+	if resp != nil && resp.Header.Get("Sec-WebSocket-Protocol") == subprotocolGraphQLWS {
+		subprotocol = subprotocolGraphQLWS
+	}
+	return conn, subprotocol, serverDone
+}
+
+func TestSubscriptionHandshakeReadLoopAndNext(t *testing.T) {
+	type msg struct {
+		Value int `graphql:"value"`
+	}
+
+	conn, subprotocol, serverDone := dialFakeSubscriptionServer(t, func(c *websocket.Conn) {
+		var init wsFrame
+		if err := c.ReadJSON(&init); err != nil || init.Type != "connection_init" {
+			t.Errorf("expected connection_init, got %+v (err %v)", init, err)
+			return
+		}
+		var initPayload map[string]string
+		if err := json.Unmarshal(init.Payload, &initPayload); err != nil || initPayload["authToken"] != "secret" {
+			t.Errorf("connection_init payload = %s, want {\"authToken\":\"secret\"}", init.Payload)
+		}
+		if err := c.WriteJSON(wsFrame{Type: "connection_ack"}); err != nil {
+			return
+		}
+
+		var start wsFrame
+		if err := c.ReadJSON(&start); err != nil || start.Type != "subscribe" {
+			t.Errorf("expected subscribe, got %+v (err %v)", start, err)
+			return
+		}
+
+		// A keep-alive/ping frame shouldn't surface as data or an error.
+		_ = c.WriteJSON(wsFrame{Type: "ping"})
+
+		dataPayload, _ := json.Marshal(map[string]interface{}{"data": map[string]interface{}{"value": 1}})
+		_ = c.WriteJSON(wsFrame{ID: start.ID, Type: "next", Payload: dataPayload})
+
+		errPayload, _ := json.Marshal([]map[string]interface{}{{"message": "boom"}})
+		_ = c.WriteJSON(wsFrame{ID: start.ID, Type: "error", Payload: errPayload})
+
+		_ = c.WriteJSON(wsFrame{ID: start.ID, Type: "complete"})
+	})
+
+	if subprotocol != subprotocolGraphQLTransportWS {
+		t.Fatalf("negotiated subprotocol = %q, want %q", subprotocol, subprotocolGraphQLTransportWS)
+	}
+
+	sub := &Subscription{
+		conn:        conn,
+		subprotocol: subprotocol,
+		id:          "1",
+		outType:     reflect.TypeOf(msg{}),
+		updates:     make(chan interface{}, 1),
+		errs:        make(chan error, 1),
+		done:        make(chan struct{}),
+	}
+
+	if err := sub.handshake(context.Background(), map[string]string{"authToken": "secret"}); err != nil {
+		t.Fatalf("handshake: %v", err)
+	}
+	if err := sub.sendStart(nil, "subscription{value}"); err != nil {
+		t.Fatalf("sendStart: %v", err)
+	}
+	go sub.readLoop()
+
+	var got msg
+	if err := sub.Next(context.Background(), &got); err != nil {
+		t.Fatalf("Next (data): %v", err)
+	}
+	if got.Value != 1 {
+		t.Errorf("Value = %d, want 1", got.Value)
+	}
+
+	var gqlErrs GraphQLErrors
+	if err := sub.Next(context.Background(), &got); !errors.As(err, &gqlErrs) {
+		t.Fatalf("Next (error) = %v, want GraphQLErrors", err)
+	} else if len(gqlErrs) != 1 || gqlErrs[0].Message != "boom" {
+		t.Errorf("Next (error) = %+v, want [{Message: boom}]", gqlErrs)
+	}
+
+	if err := sub.Next(context.Background(), &got); err != io.EOF {
+		t.Errorf("Next (complete) = %v, want io.EOF", err)
+	}
+
+	if err := sub.Close(); err != nil {
+		t.Errorf("Close: %v", err)
+	}
+	<-serverDone
+}
+
+func TestSubscriptionHandshakeRejectsNonAck(t *testing.T) {
+	conn, _, serverDone := dialFakeSubscriptionServer(t, func(c *websocket.Conn) {
+		var init wsFrame
+		if err := c.ReadJSON(&init); err != nil || init.Type != "connection_init" {
+			t.Errorf("expected connection_init, got %+v (err %v)", init, err)
+			return
+		}
+		_ = c.WriteJSON(wsFrame{Type: "connection_error", Payload: json.RawMessage(`"unauthorized"`)})
+	})
+
+	sub := &Subscription{
+		conn:        conn,
+		subprotocol: subprotocolGraphQLTransportWS,
+		id:          "1",
+		updates:     make(chan interface{}, 1),
+		errs:        make(chan error, 1),
+		done:        make(chan struct{}),
+	}
+
+	err := sub.handshake(context.Background(), nil)
+	if err == nil {
+		t.Fatal("handshake succeeded despite a connection_error reply, want an error")
+	}
+
+	_ = conn.Close()
+	<-serverDone
+}
@@ -0,0 +1,180 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"sort"
+	"strconv"
+)
+
+// Upload represents a single file to be sent as part of a multipart GraphQL
+// request. Place a value or pointer of this type anywhere in the variables
+// passed to Query or Mutate (directly, or nested in a map/slice) and
+// Client.do will switch the request encoding from application/json to
+// multipart/form-data per the GraphQL multipart request specification:
+// https://github.com/jaydenseric/graphql-multipart-request-spec.
+type Upload struct {
+	Filename    string
+	ContentType string
+	Body        io.Reader
+}
+
+// WithMaxUploadSize limits the total number of bytes read from all Upload
+// values across a single request. Exceeding it aborts the request with an
+// error instead of streaming an unbounded amount of data.
+func WithMaxUploadSize(n int64) ClientOption {
+	return func(c *Client) { c.maxUploadSize = n }
+}
+
+// uploadRef is a single Upload found within a request's variables, along
+// with its dotted path (e.g. "variables.file", "variables.files.0").
+type uploadRef struct {
+	path   string
+	upload *Upload
+}
+
+// findUploads walks v, which must be built from the same
+// map[string]interface{}/[]interface{}/scalar shapes JSON encoding accepts,
+// looking for Upload and *Upload values.
+func findUploads(path string, v interface{}) []uploadRef {
+	switch val := v.(type) {
+	case *Upload:
+		return []uploadRef{{path: path, upload: val}}
+	case Upload:
+		return []uploadRef{{path: path, upload: &val}}
+	case map[string]interface{}:
+		keys := make([]string, 0, len(val))
+		for k := range val {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		var refs []uploadRef
+		for _, k := range keys {
+			refs = append(refs, findUploads(path+"."+k, val[k])...)
+		}
+		return refs
+	case []interface{}:
+		var refs []uploadRef
+		for i, e := range val {
+			refs = append(refs, findUploads(path+"."+strconv.Itoa(i), e)...)
+		}
+		return refs
+	default:
+		return nil
+	}
+}
+
+// nullUploads returns a deep copy of v with every Upload/*Upload value
+// replaced by nil, as required for the multipart spec's "operations" part.
+func nullUploads(v interface{}) interface{} {
+	switch val := v.(type) {
+	case *Upload:
+		return nil
+	case Upload:
+		return nil
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(val))
+		for k, e := range val {
+			out[k] = nullUploads(e)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(val))
+		for i, e := range val {
+			out[i] = nullUploads(e)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// doUpload sends query and variables as a multipart/form-data request,
+// with the file in each of uploads as its own part. The whole body, file
+// contents included, is built in memory before the request is sent.
+func (c *Client) doUpload(ctx context.Context, query string, variables map[string]interface{}, v interface{}, uploads []uploadRef, opts []RequestOption) error {
+	req, err := c.newUploadRequest(ctx, query, variables, uploads)
+	if err != nil {
+		return err
+	}
+	return c.execute(req, opts, v)
+}
+
+// newUploadRequest builds the multipart/form-data request for an operation
+// whose variables contain one or more Upload values: an "operations" part
+// with the usual JSON body (files replaced by null), a "map" part pointing
+// each file part back at its variable path, and one part per file.
+func (c *Client) newUploadRequest(ctx context.Context, query string, variables map[string]interface{}, uploads []uploadRef) (*http.Request, error) {
+	opVariables, _ := nullUploads(variables).(map[string]interface{})
+	operations := struct {
+		Query     string                 `json:"query"`
+		Variables map[string]interface{} `json:"variables,omitempty"`
+	}{Query: query, Variables: opVariables}
+	opJSON, err := json.Marshal(operations)
+	if err != nil {
+		return nil, err
+	}
+
+	fileMap := make(map[string][]string, len(uploads))
+	for i, u := range uploads {
+		fileMap[strconv.Itoa(i)] = []string{u.path}
+	}
+	mapJSON, err := json.Marshal(fileMap)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	if err := w.WriteField("operations", string(opJSON)); err != nil {
+		return nil, err
+	}
+	if err := w.WriteField("map", string(mapJSON)); err != nil {
+		return nil, err
+	}
+
+	var total int64
+	for i, u := range uploads {
+		part, err := w.CreatePart(filePartHeader(strconv.Itoa(i), u.upload))
+		if err != nil {
+			return nil, err
+		}
+		body := u.upload.Body
+		if c.maxUploadSize > 0 {
+			body = io.LimitReader(body, c.maxUploadSize-total+1)
+		}
+		n, err := io.Copy(part, body)
+		if err != nil {
+			return nil, err
+		}
+		total += n
+		if c.maxUploadSize > 0 && total > c.maxUploadSize {
+			return nil, fmt.Errorf("graphql: upload exceeds max size of %d bytes", c.maxUploadSize)
+		}
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, &buf)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	return req, nil
+}
+
+func filePartHeader(field string, u *Upload) textproto.MIMEHeader {
+	h := make(textproto.MIMEHeader)
+	h.Set("Content-Disposition", fmt.Sprintf(`form-data; name=%q; filename=%q`, field, u.Filename))
+	if u.ContentType != "" {
+		h.Set("Content-Type", u.ContentType)
+	}
+	return h
+}
@@ -0,0 +1,72 @@
+package graphql
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestFindUploadsAcceptsValueAndPointer(t *testing.T) {
+	ptrUpload := &Upload{Filename: "a.txt"}
+	valUpload := Upload{Filename: "b.txt"}
+
+	variables := map[string]interface{}{
+		"file":  ptrUpload,
+		"file2": valUpload,
+	}
+
+	refs := findUploads("variables", variables)
+	if len(refs) != 2 {
+		t.Fatalf("got %d upload refs, want 2: %+v", len(refs), refs)
+	}
+
+	byPath := map[string]*uploadRef{}
+	for i := range refs {
+		byPath[refs[i].path] = &refs[i]
+	}
+	if refs := byPath["variables.file"]; refs == nil || refs.upload.Filename != "a.txt" {
+		t.Errorf("variables.file ref missing or wrong: %+v", byPath["variables.file"])
+	}
+	if refs := byPath["variables.file2"]; refs == nil || refs.upload.Filename != "b.txt" {
+		t.Errorf("variables.file2 ref missing or wrong: %+v", byPath["variables.file2"])
+	}
+}
+
+func TestNullUploadsReplacesValueAndPointer(t *testing.T) {
+	variables := map[string]interface{}{
+		"file":  &Upload{Filename: "a.txt"},
+		"file2": Upload{Filename: "b.txt"},
+		"name":  "unrelated",
+	}
+
+	out, ok := nullUploads(variables).(map[string]interface{})
+	if !ok {
+		t.Fatalf("nullUploads did not return a map[string]interface{}: %T", out)
+	}
+	if out["file"] != nil {
+		t.Errorf("file = %v, want nil", out["file"])
+	}
+	if out["file2"] != nil {
+		t.Errorf("file2 = %v, want nil", out["file2"])
+	}
+	if out["name"] != "unrelated" {
+		t.Errorf("name = %v, want unrelated", out["name"])
+	}
+}
+
+func TestNewUploadRequestBuildsMultipartParts(t *testing.T) {
+	c := NewClient("http://example.invalid/graphql", nil)
+	variables := map[string]interface{}{
+		"file": Upload{Filename: "hello.txt", Body: strings.NewReader("hello")},
+	}
+	uploads := findUploads("variables", variables)
+
+	req, err := c.newUploadRequest(context.Background(), "mutation { upload(file: $file) }", variables, uploads)
+	if err != nil {
+		t.Fatal(err)
+	}
+	contentType := req.Header.Get("Content-Type")
+	if !strings.HasPrefix(contentType, "multipart/form-data") {
+		t.Fatalf("Content-Type = %q, want multipart/form-data", contentType)
+	}
+}